@@ -9,29 +9,108 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/acorn-io/gptscript/pkg/builtin/sandbox"
 	"github.com/acorn-io/gptscript/pkg/types"
 )
 
-var Tools = map[string]types.Tool{
+// registry holds every builtin tool, whether defined in this package or
+// registered by an external Go module embedding gptscript as a library.
+type registry struct {
+	mu    sync.RWMutex
+	tools map[string]types.Tool
+}
+
+var defaultRegistry = &registry{tools: map[string]types.Tool{}}
+
+// Register adds tool under name to the default registry so that it is
+// returned by ListTools and Builtin. It panics if name is already
+// registered, since two packages fighting over the same tool name at
+// init() time is a programming error, not something to handle at runtime.
+func Register(name string, tool types.Tool) {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+
+	if _, ok := defaultRegistry.tools[name]; ok {
+		panic(fmt.Sprintf("builtin %s is already registered", name))
+	}
+	defaultRegistry.tools[name] = tool
+}
+
+// httpClient is shared by sys.http.get, sys.http.download and
+// sys.http.request so that connections to the same host are reused instead
+// of every call paying a fresh dial+TLS handshake. sys.http.post sends
+// through http.DefaultClient.
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		MaxIdleConnsPerHost: 10,
+	},
+}
+
+const defaultHTTPTimeout = 30 * time.Second
+
+func init() {
+	for name, tool := range builtinTools {
+		Register(name, tool)
+	}
+}
+
+var builtinTools = map[string]types.Tool{
 	"sys.read": {
 		Description: "Reads the contents of a file",
 		Arguments: types.ObjectSchema(
-			"filename", "The name of the file to read"),
-		BuiltinFunc: SysRead,
+			"filename", "The name of the file to read",
+			"timeout", "Optional duration string, such as \"10s\"; the read fails if it has elapsed before the read starts, a read in progress is not interrupted",
+			"deadline", "Optional RFC3339 timestamp; the read fails if it has passed before the read starts, a read in progress is not interrupted"),
+		BuiltinFunc:       SysRead,
+		BuiltinStreamFunc: SysReadStream,
 	},
 	"sys.write": {
 		Description: "Write the contents to a file",
 		Arguments: types.ObjectSchema(
 			"filename", "The name of the file to write to",
-			"content", "The content to write"),
+			"content", "The content to write",
+			"timeout", "Optional duration string, such as \"10s\"; the write fails if it has elapsed before the write starts, a write in progress is not interrupted",
+			"deadline", "Optional RFC3339 timestamp; the write fails if it has passed before the write starts, a write in progress is not interrupted"),
 		BuiltinFunc: SysWrite,
 	},
+	"sys.ls": {
+		Description: "Lists the contents of a directory",
+		Arguments: types.ObjectSchema(
+			"dir", "The directory to list"),
+		BuiltinFunc: SysLS,
+	},
+	"sys.stat": {
+		Description: "Gets information about a file or directory such as its size and modification time",
+		Arguments: types.ObjectSchema(
+			"filename", "The name of the file or directory to stat"),
+		BuiltinFunc: SysStat,
+	},
+	"sys.remove": {
+		Description: "Removes a file",
+		Arguments: types.ObjectSchema(
+			"filename", "The name of the file to remove"),
+		BuiltinFunc: SysRemove,
+	},
 	"sys.http.get": {
 		Description: "Download the contents of a http or https URL",
 		Arguments: types.ObjectSchema(
-			"url", "The URL to download"),
-		BuiltinFunc: SysHTTPGet,
+			"url", "The URL to download",
+			"timeout", "Optional duration string, such as \"10s\", after which the request is aborted",
+			"deadline", "Optional RFC3339 timestamp after which the request is aborted"),
+		BuiltinFunc:       SysHTTPGet,
+		BuiltinStreamFunc: SysHTTPGetStream,
+	},
+	"sys.http.download": {
+		Description: "Download the contents of a http or https URL directly to a file",
+		Arguments: types.ObjectSchema(
+			"url", "The URL to download",
+			"filename", "The name of the file to write the downloaded contents to",
+			"timeout", "Optional duration string, such as \"10s\", after which the request is aborted",
+			"deadline", "Optional RFC3339 timestamp after which the request is aborted"),
+		BuiltinFunc: SysHTTPDownload,
 	},
 	"sys.abort": {
 		Description: "Aborts execution",
@@ -40,21 +119,45 @@ var Tools = map[string]types.Tool{
 		),
 		BuiltinFunc: SysAbort,
 	},
+	"sys.fail": {
+		Description: "Reports a non-fatal error back to the caller as structured JSON instead of aborting the tool call",
+		Arguments: types.ObjectSchema(
+			"code", "A short machine-readable code identifying the failure, such as \"not_found\"",
+			"message", "A human-readable description of the failure",
+		),
+		BuiltinFunc: SysFail,
+	},
 	"sys.http.post": {
 		Description: "Write contents to a http or https URL using the POST method",
 		Arguments: types.ObjectSchema(
 			"url", "The URL to POST to",
 			"content", "The content to POST",
-			"contentType", "The \"content type\" of the content such as application/json or text/plain"),
+			"contentType", "The \"content type\" of the content such as application/json or text/plain",
+			"timeout", "Optional duration string, such as \"10s\", after which the request is aborted",
+			"deadline", "Optional RFC3339 timestamp after which the request is aborted"),
 		BuiltinFunc: SysHTTPPost,
 	},
+	"sys.http.request": {
+		Description: "Make an arbitrary http or https request with any method, headers, and body",
+		Arguments: types.ObjectSchema(
+			"method", "The HTTP method to use, such as GET, POST, PUT, PATCH, or DELETE",
+			"url", "The URL to request",
+			"headers", "A JSON object of header names to values to set on the request, such as {\"Authorization\": \"Bearer ...\"}",
+			"body", "The request body to send, if any",
+			"timeout", "Optional duration string, such as \"10s\" or \"2m\", after which the request is aborted. Defaults to 30s",
+			"deadline", "Optional RFC3339 timestamp after which the request is aborted",
+			"followRedirects", "Optional boolean, if \"false\" redirects are not followed. Defaults to true"),
+		BuiltinFunc: SysHTTPRequest,
+	},
 }
 
 func ListTools() (result []types.Tool) {
-	var keys []string
-	for k := range Tools {
+	defaultRegistry.mu.RLock()
+	keys := make([]string, 0, len(defaultRegistry.tools))
+	for k := range defaultRegistry.tools {
 		keys = append(keys, k)
 	}
+	defaultRegistry.mu.RUnlock()
 
 	sort.Strings(keys)
 	for _, key := range keys {
@@ -66,13 +169,26 @@ func ListTools() (result []types.Tool) {
 }
 
 func Builtin(name string) (types.Tool, bool) {
-	t, ok := Tools[name]
+	defaultRegistry.mu.RLock()
+	t, ok := defaultRegistry.tools[name]
+	defaultRegistry.mu.RUnlock()
+
 	t.Name = name
 	t.ID = name
 	t.Instructions = "#!" + name
 	return t, ok
 }
 
+// resolvePath runs filename through the sandbox built from env, so that
+// every filesystem builtin enforces the same root and allow/deny policy.
+func resolvePath(env []string, filename string) (string, error) {
+	sb, err := sandbox.FromEnv(env)
+	if err != nil {
+		return "", err
+	}
+	return sb.Resolve(filename)
+}
+
 func SysRead(ctx context.Context, env []string, input string) (string, error) {
 	var params struct {
 		Filename string `json:"filename,omitempty"`
@@ -81,8 +197,25 @@ func SysRead(ctx context.Context, env []string, input string) (string, error) {
 		return "", err
 	}
 
-	log.Debugf("Reading file %s", params.Filename)
-	data, err := os.ReadFile(params.Filename)
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	path, err := resolvePath(env, params.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	// os.ReadFile has no context-aware variant, so the best we can do is
+	// fail fast if the deadline has already passed before the read starts.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	log.Debugf("Reading file %s", path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", err
 	}
@@ -90,6 +223,36 @@ func SysRead(ctx context.Context, env []string, input string) (string, error) {
 	return string(data), nil
 }
 
+// SysReadStream is the streaming counterpart to SysRead: it hands back the
+// open file directly instead of buffering its contents into a string.
+func SysReadStream(ctx context.Context, env []string, input string) (io.ReadCloser, error) {
+	var params struct {
+		Filename string `json:"filename,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	path, err := resolvePath(env, params.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// As in SysRead, the deadline is only checked before the file is opened.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Streaming file %s", path)
+	return os.Open(path)
+}
+
 func SysWrite(ctx context.Context, env []string, input string) (string, error) {
 	var params struct {
 		Filename string `json:"filename,omitempty"`
@@ -99,11 +262,162 @@ func SysWrite(ctx context.Context, env []string, input string) (string, error) {
 		return "", err
 	}
 
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	path, err := resolvePath(env, params.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	// os.WriteFile has no context-aware variant, so the best we can do is
+	// fail fast if the deadline has already passed before the write starts.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	data := []byte(params.Content)
-	msg := fmt.Sprintf("Wrote %d bytes to file %s", len(data), params.Filename)
+	msg := fmt.Sprintf("Wrote %d bytes to file %s", len(data), path)
 	log.Debugf(msg)
 
-	return "", os.WriteFile(params.Filename, data, 0644)
+	return "", os.WriteFile(path, data, 0644)
+}
+
+type dirEntryResult struct {
+	Name  string `json:"name"`
+	IsDir bool   `json:"isDir"`
+	Size  int64  `json:"size"`
+}
+
+func SysLS(ctx context.Context, env []string, input string) (string, error) {
+	var params struct {
+		Dir string `json:"dir,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", err
+	}
+
+	dir := params.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	path, err := resolvePath(env, dir)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Listing directory %s", path)
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+
+	result := make([]dirEntryResult, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		result = append(result, dirEntryResult{
+			Name:  entry.Name(),
+			IsDir: entry.IsDir(),
+			Size:  info.Size(),
+		})
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+type statResult struct {
+	Name    string `json:"name"`
+	IsDir   bool   `json:"isDir"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"modTime"`
+}
+
+func SysStat(ctx context.Context, env []string, input string) (string, error) {
+	var params struct {
+		Filename string `json:"filename,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", err
+	}
+
+	path, err := resolvePath(env, params.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Statting %s", path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(statResult{
+		Name:    info.Name(),
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func SysRemove(ctx context.Context, env []string, input string) (string, error) {
+	var params struct {
+		Filename string `json:"filename,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", err
+	}
+
+	path, err := resolvePath(env, params.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Removing %s", path)
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Removed %s", params.Filename), nil
+}
+
+// bodySnippetLimit bounds how much of a failed response body is attached to
+// a ToolError's Details, so a large error page doesn't blow out the tool
+// output.
+const bodySnippetLimit = 512
+
+func httpStatusError(url string, resp *http.Response, body []byte) *types.ToolError {
+	snippet := string(body)
+	if len(snippet) > bodySnippetLimit {
+		snippet = snippet[:bodySnippetLimit]
+	}
+
+	return &types.ToolError{
+		Code:      "http_status",
+		Message:   fmt.Sprintf("failed to request %s: %s", url, resp.Status),
+		Retriable: resp.StatusCode >= 500,
+		Details: map[string]any{
+			"url":        url,
+			"statusCode": resp.StatusCode,
+			"body":       snippet,
+		},
+	}
 }
 
 func SysHTTPGet(ctx context.Context, env []string, input string) (string, error) {
@@ -114,8 +428,132 @@ func SysHTTPGet(ctx context.Context, env []string, input string) (string, error)
 		return "", err
 	}
 
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
 	log.Debugf("http get %s", params.URL)
-	resp, err := http.Get(params.URL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", httpStatusError(params.URL, resp, data)
+	}
+
+	return string(data), nil
+}
+
+// cancelOnCloseReader releases a deadline's cancel func when the wrapped
+// body is closed, instead of the instant the builtin returns, since the
+// caller goes on reading the body well after that.
+type cancelOnCloseReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnCloseReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+// SysHTTPGetStream is the streaming counterpart to SysHTTPGet: it hands back
+// the live response body instead of buffering the whole download into a
+// string, so the caller decides how much to read and when.
+func SysHTTPGetStream(ctx context.Context, env []string, input string) (io.ReadCloser, error) {
+	var params struct {
+		URL string `json:"url,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("http get (stream) %s", params.URL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		defer cancel()
+		return nil, fmt.Errorf("failed to download %s: %s", params.URL, resp.Status)
+	}
+
+	return &cancelOnCloseReader{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// progressReader logs download progress at regular byte intervals instead
+// of only reporting a single final size once the whole body has been read.
+type progressReader struct {
+	r     io.Reader
+	url   string
+	total int64
+	next  int64
+}
+
+const progressLogInterval = 1 << 20 // log every MiB
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.total += int64(n)
+	if p.total >= p.next {
+		log.Debugf("downloading %s: %d bytes", p.url, p.total)
+		p.next = p.total + progressLogInterval
+	}
+	return n, err
+}
+
+func SysHTTPDownload(ctx context.Context, env []string, input string) (string, error) {
+	var params struct {
+		URL      string `json:"url,omitempty"`
+		Filename string `json:"filename,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", err
+	}
+
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	path, err := resolvePath(env, params.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -124,12 +562,18 @@ func SysHTTPGet(ctx context.Context, env []string, input string) (string, error)
 		return "", fmt.Errorf("failed to download %s: %s", params.URL, resp.Status)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	out, err := os.Create(path)
 	if err != nil {
 		return "", err
 	}
+	defer out.Close()
 
-	return string(data), nil
+	written, err := io.Copy(out, &progressReader{r: resp.Body, url: params.URL})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Downloaded %d bytes from %s to %s", written, params.URL, params.Filename), nil
 }
 
 func SysHTTPPost(ctx context.Context, env []string, input string) (string, error) {
@@ -142,6 +586,12 @@ func SysHTTPPost(ctx context.Context, env []string, input string) (string, error
 		return "", err
 	}
 
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, params.URL, strings.NewReader(params.Content))
 	if err != nil {
 		return "", err
@@ -156,14 +606,93 @@ func SysHTTPPost(ctx context.Context, env []string, input string) (string, error
 	}
 	defer resp.Body.Close()
 
-	_, _ = io.ReadAll(resp.Body)
+	data, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode > 399 {
-		return "", fmt.Errorf("failed to post %s: %s", params.URL, resp.Status)
+		return "", httpStatusError(params.URL, resp, data)
 	}
 
 	return fmt.Sprintf("Wrote %d to %s", len([]byte(params.Content)), params.URL), nil
 }
 
+type httpRequestResult struct {
+	Status     string              `json:"status"`
+	StatusCode int                 `json:"statusCode"`
+	Headers    map[string][]string `json:"headers"`
+	Body       string              `json:"body"`
+}
+
+func SysHTTPRequest(ctx context.Context, env []string, input string) (string, error) {
+	var params struct {
+		Method          string            `json:"method,omitempty"`
+		URL             string            `json:"url,omitempty"`
+		Headers         map[string]string `json:"headers,omitempty"`
+		Body            string            `json:"body,omitempty"`
+		FollowRedirects *bool             `json:"followRedirects,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", err
+	}
+
+	method := params.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// timeout/deadline are read directly from input by deadlineFromInput.
+	ctx, cancel, err := deadlineFromInput(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeout(ctx, defaultHTTPTimeout)
+		defer timeoutCancel()
+	}
+
+	log.Debugf("http %s %s", method, params.URL)
+	req, err := http.NewRequestWithContext(ctx, method, params.URL, strings.NewReader(params.Body))
+	if err != nil {
+		return "", err
+	}
+	for k, v := range params.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := httpClient
+	if params.FollowRedirects != nil && !*params.FollowRedirects {
+		clientCopy := *httpClient
+		clientCopy.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		client = &clientCopy
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(httpRequestResult{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       string(data),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
 func SysAbort(ctx context.Context, env []string, input string) (string, error) {
 	var params struct {
 		Message string `json:"message,omitempty"`
@@ -172,4 +701,31 @@ func SysAbort(ctx context.Context, env []string, input string) (string, error) {
 		return "", err
 	}
 	return "", fmt.Errorf("ABORT: %s", params.Message)
-}
\ No newline at end of file
+}
+
+// SysFail is the non-fatal counterpart to SysAbort: instead of terminating
+// the tool call, it reports the failure as a types.ToolError JSON output so
+// the LLM can inspect it and decide how to proceed.
+func SysFail(ctx context.Context, env []string, input string) (string, error) {
+	var params struct {
+		Code      string         `json:"code,omitempty"`
+		Message   string         `json:"message,omitempty"`
+		Retriable bool           `json:"retriable,omitempty"`
+		Details   map[string]any `json:"details,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(types.ToolError{
+		Code:      params.Code,
+		Message:   params.Message,
+		Retriable: params.Retriable,
+		Details:   params.Details,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
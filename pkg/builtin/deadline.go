@@ -0,0 +1,47 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// deadlineFromInput reads an optional "timeout" (duration string, e.g.
+// "10s") or "deadline" (RFC3339 timestamp) out of a builtin's JSON input and
+// derives a context bound by whichever is set, via context.WithDeadline/
+// WithTimeout so that ctx.Deadline() correctly reports the caller's
+// deadline to callers such as SysHTTPRequest that need to tell "no deadline
+// set" apart from "one was set". A deadline already in the past cancels the
+// returned context immediately, which is context.WithDeadline's documented
+// behavior. The returned cancel must always be called, typically via defer,
+// to release resources whether or not the deadline is ever reached.
+func deadlineFromInput(ctx context.Context, input string) (context.Context, context.CancelFunc, error) {
+	var params struct {
+		Timeout  string `json:"timeout,omitempty"`
+		Deadline string `json:"deadline,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case params.Deadline != "":
+		deadline, err := time.Parse(time.RFC3339, params.Deadline)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid deadline %q: %w", params.Deadline, err)
+		}
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		return ctx, cancel, nil
+	case params.Timeout != "":
+		d, err := time.ParseDuration(params.Timeout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timeout %q: %w", params.Timeout, err)
+		}
+		ctx, cancel := context.WithTimeout(ctx, d)
+		return ctx, cancel, nil
+	default:
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+}
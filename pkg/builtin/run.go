@@ -0,0 +1,52 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/acorn-io/gptscript/pkg/types"
+)
+
+// Run invokes the named builtin, preferring its BuiltinStreamFunc over
+// BuiltinFunc when both are set so that callers able to consume a stream
+// (e.g. a large download) aren't forced to wait for the whole output to
+// buffer first. The result is always an io.ReadCloser; output from a
+// buffered BuiltinFunc is wrapped in a no-op closer.
+//
+// When a BuiltinFunc fails with a *types.ToolError, Run marshals it to JSON
+// and returns it as output with a nil error instead of collapsing it to the
+// error's plain-text Message, so the caller gets Code/Retriable/Details
+// rather than just a string.
+func Run(ctx context.Context, name string, env []string, input string) (io.ReadCloser, error) {
+	tool, ok := Builtin(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown builtin %s", name)
+	}
+
+	if tool.BuiltinStreamFunc != nil {
+		return tool.BuiltinStreamFunc(ctx, env, input)
+	}
+
+	if tool.BuiltinFunc == nil {
+		return nil, fmt.Errorf("builtin %s has no implementation", name)
+	}
+
+	out, err := tool.BuiltinFunc(ctx, env, input)
+	if err != nil {
+		var toolErr *types.ToolError
+		if errors.As(err, &toolErr) {
+			data, marshalErr := json.Marshal(toolErr)
+			if marshalErr != nil {
+				return nil, marshalErr
+			}
+			return io.NopCloser(strings.NewReader(string(data))), nil
+		}
+		return nil, err
+	}
+
+	return io.NopCloser(strings.NewReader(out)), nil
+}
@@ -0,0 +1,143 @@
+// Package sandbox resolves the file paths used by the filesystem builtins
+// against a root directory so that a tool cannot read or write anywhere
+// outside of it, and optionally against an allow/deny glob list.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox enforces a root directory and an optional allow/deny glob list
+// for every path a filesystem builtin touches.
+type Sandbox struct {
+	Root  string
+	Allow []string
+	Deny  []string
+}
+
+// FromEnv builds a Sandbox from the env []string passed to a BuiltinFunc.
+// Root defaults to the current working directory and can be overridden with
+// GPTSCRIPT_ROOT. GPTSCRIPT_ALLOW and GPTSCRIPT_DENY are comma-separated
+// glob lists matched against the path relative to Root, e.g.
+// GPTSCRIPT_ALLOW=./data/**,./tmp/*.txt.
+func FromEnv(env []string) (*Sandbox, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sandbox{Root: root}
+	for _, kv := range env {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "GPTSCRIPT_ROOT":
+			s.Root = v
+		case "GPTSCRIPT_ALLOW":
+			s.Allow = splitGlobs(v)
+		case "GPTSCRIPT_DENY":
+			s.Deny = splitGlobs(v)
+		}
+	}
+
+	return s, nil
+}
+
+func splitGlobs(v string) (result []string) {
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return
+}
+
+// Resolve validates filename against the sandbox root and allow/deny lists
+// and returns the absolute path that should be used on disk. Symlinks are
+// resolved before the containment check, so a symlink inside Root pointing
+// outside of it cannot be used to escape the sandbox.
+func (s *Sandbox) Resolve(filename string) (string, error) {
+	abs := filename
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(s.Root, abs)
+	}
+	abs = filepath.Clean(abs)
+
+	root, err := filepath.EvalSymlinks(s.Root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve sandbox root %s: %w", s.Root, err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		// filename doesn't exist yet, e.g. sys.write creating a new file, so
+		// resolve its parent directory instead and rejoin it with the
+		// original base name. If abs itself exists it is a symlink whose
+		// target is missing; writing through it could create a file outside
+		// Root, so it is rejected.
+		if _, lerr := os.Lstat(abs); lerr == nil {
+			return "", fmt.Errorf("path %s is a symlink to a missing target", filename)
+		}
+		parent, err := filepath.EvalSymlinks(filepath.Dir(abs))
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", filename, err)
+		}
+		resolved = filepath.Join(parent, filepath.Base(abs))
+	} else if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", filename, err)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s relative to sandbox root %s: %w", filename, s.Root, err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s escapes sandbox root %s", filename, s.Root)
+	}
+
+	if err := s.checkGlobs(rel); err != nil {
+		return "", err
+	}
+
+	return resolved, nil
+}
+
+func (s *Sandbox) checkGlobs(rel string) error {
+	for _, pattern := range s.Deny {
+		if match(pattern, rel) {
+			return fmt.Errorf("path %s is denied by sandbox policy", rel)
+		}
+	}
+
+	if len(s.Allow) == 0 {
+		return nil
+	}
+	for _, pattern := range s.Allow {
+		if match(pattern, rel) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("path %s is not in the sandbox allow-list", rel)
+}
+
+// match supports a trailing "/**" for matching an entire subtree in
+// addition to the usual filepath.Match single-segment wildcards.
+func match(pattern, name string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return name == prefix || strings.HasPrefix(name, prefix+"/")
+	}
+
+	ok, err := filepath.Match(pattern, name)
+	return err == nil && ok
+}
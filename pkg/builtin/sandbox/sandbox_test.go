@@ -0,0 +1,116 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	s, err := FromEnv([]string{
+		"GPTSCRIPT_ROOT=/tmp/example",
+		"GPTSCRIPT_ALLOW=./data/**,./tmp/*.txt",
+		"GPTSCRIPT_DENY=./data/secret/**",
+		"UNRELATED=ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Root != "/tmp/example" {
+		t.Fatalf("Root = %q, want /tmp/example", s.Root)
+	}
+	if len(s.Allow) != 2 || s.Allow[0] != "./data/**" || s.Allow[1] != "./tmp/*.txt" {
+		t.Fatalf("Allow = %v, want [./data/** ./tmp/*.txt]", s.Allow)
+	}
+	if len(s.Deny) != 1 || s.Deny[0] != "./data/secret/**" {
+		t.Fatalf("Deny = %v, want [./data/secret/**]", s.Deny)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tmp := t.TempDir()
+
+	root := filepath.Join(tmp, "root")
+	outside := filepath.Join(tmp, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "inside.txt"), []byte("inside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		sandbox  Sandbox
+		filename string
+		wantErr  bool
+	}{
+		{
+			name:     "file inside root resolves",
+			sandbox:  Sandbox{Root: root},
+			filename: "inside.txt",
+		},
+		{
+			name:     "dot-dot traversal is rejected",
+			sandbox:  Sandbox{Root: root},
+			filename: "../outside/secret.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "absolute path outside root is rejected",
+			sandbox:  Sandbox{Root: root},
+			filename: filepath.Join(outside, "secret.txt"),
+			wantErr:  true,
+		},
+		{
+			name:     "symlink inside root pointing outside is rejected",
+			sandbox:  Sandbox{Root: root},
+			filename: "link/secret.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "not-yet-existing file is resolved via its parent directory",
+			sandbox:  Sandbox{Root: root},
+			filename: "new-file.txt",
+		},
+		{
+			name:     "deny takes precedence over allow",
+			sandbox:  Sandbox{Root: root, Allow: []string{"inside.txt"}, Deny: []string{"inside.txt"}},
+			filename: "inside.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "allow list permits a matching path",
+			sandbox:  Sandbox{Root: root, Allow: []string{"inside.txt"}},
+			filename: "inside.txt",
+		},
+		{
+			name:     "allow list rejects a non-matching path",
+			sandbox:  Sandbox{Root: root, Allow: []string{"other.txt"}},
+			filename: "inside.txt",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.sandbox.Resolve(tt.filename)
+			if tt.wantErr && err == nil {
+				t.Fatalf("Resolve(%q) = nil error, want error", tt.filename)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Resolve(%q) = %v, want no error", tt.filename, err)
+			}
+		})
+	}
+}
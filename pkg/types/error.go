@@ -0,0 +1,16 @@
+package types
+
+// ToolError is a structured failure a builtin can return instead of a plain
+// error, so that the calling LLM can inspect the failure and react
+// programmatically (for example retrying) rather than only seeing an
+// opaque error string.
+type ToolError struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Retriable bool           `json:"retriable"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+func (e *ToolError) Error() string {
+	return e.Message
+}
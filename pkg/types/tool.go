@@ -0,0 +1,63 @@
+// Package types holds the shared data structures used to describe and
+// invoke tools, independent of whether a tool is a builtin implemented in
+// Go or one parsed from a gptscript file.
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// Tool represents a single callable tool.
+type Tool struct {
+	ID           string
+	Name         string
+	Description  string
+	Instructions string
+	Arguments    string
+
+	BuiltinFunc       BuiltinFunc
+	BuiltinStreamFunc BuiltinStreamFunc
+}
+
+// BuiltinFunc is implemented by builtins such as the ones in pkg/builtin. It
+// receives the raw JSON arguments produced by the LLM as input along with
+// the process environment the tool call should run with, and returns the
+// tool's full output buffered into a string.
+type BuiltinFunc func(ctx context.Context, env []string, input string) (string, error)
+
+// BuiltinStreamFunc is the streaming counterpart to BuiltinFunc. Builtins
+// that can produce their output incrementally, such as large file downloads,
+// implement this instead so the caller can read the result without
+// buffering it all into memory first. When both are set on a Tool, callers
+// that support streaming should prefer BuiltinStreamFunc.
+type BuiltinStreamFunc func(ctx context.Context, env []string, input string) (io.ReadCloser, error)
+
+// ObjectSchema builds a JSON Schema object with string properties from an
+// alternating list of name, description pairs, e.g.
+// ObjectSchema("filename", "the file to read").
+func ObjectSchema(pairs ...string) string {
+	properties := map[string]any{}
+	var required []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		name, description := pairs[i], pairs[i+1]
+		properties[name] = map[string]any{
+			"type":        "string",
+			"description": description,
+		}
+		required = append(required, name)
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	})
+	if err != nil {
+		// pairs are always static, compile-time string literals.
+		panic(err)
+	}
+
+	return string(data)
+}